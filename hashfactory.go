@@ -0,0 +1,141 @@
+package imohash
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"reflect"
+
+	"github.com/cespare/xxhash/v2"
+	"golang.org/x/crypto/blake2b"
+)
+
+// HashFactory creates the hash.Hash an ImoHash samples into. The default,
+// used by New and NewCustom, is murmur3.New128. Swapping it via
+// NewWithHash lets callers trade murmur3 for a faster non-cryptographic
+// hash (xxhash) or a cryptographic one (blake2b) when imohash is used
+// for integrity checks rather than dedup. A factory's hash.Hash must
+// report a 16-byte Size, matching imohash's digest layout.
+type HashFactory func() hash.Hash
+
+// NewWithHash returns a new ImoHash that samples into the hash.Hash
+// produced by factory, using the provided sample size and sample
+// threshhold values. factory's hash.Hash must have a 16-byte Size, the
+// same as Size; NewWithHash panics otherwise, since any other width
+// would either truncate the digest (weakening collision resistance) or
+// violate the hash.Hash contract that Sum appends exactly Size() bytes.
+// An identifier derived from the hash.Hash's concrete type is mixed into
+// the hasher's state so backends of different concrete types hash the
+// same sampled bytes from different starting states; factories whose
+// hash.Hash shares a concrete type share an identifier.
+func NewWithHash(factory HashFactory, sampleSize, sampleThreshold int) ImoHash {
+	return newImoHash(factory, true, sampleSize, sampleThreshold)
+}
+
+// newImoHash is the shared constructor behind NewCustom and NewWithHash.
+// mixAlgo controls whether an algorithm identifier is mixed into the
+// hasher's state before sampling: it is false for the default murmur3
+// backend, so New and NewCustom keep producing the same digests they
+// always have, and true for any backend installed via the public
+// NewWithHash, where cross-algorithm collisions are the actual risk
+// being guarded against.
+func newImoHash(factory HashFactory, mixAlgo bool, sampleSize, sampleThreshold int) ImoHash {
+	hasher := factory()
+	if sz := hasher.Size(); sz != Size {
+		panic(fmt.Sprintf("imohash: hash backend %T produces a %d-byte digest, want %d", hasher, sz, Size))
+	}
+
+	h := ImoHash{
+		hasher:             hasher,
+		hasherFactory:      factory,
+		offsets:            OffsetsHeadMidTail(sampleSize),
+		usesDefaultOffsets: true,
+		sampleSize:         sampleSize,
+		sampleThreshold:    sampleThreshold,
+		mixAlgo:            mixAlgo,
+	}
+	if h.mixAlgo {
+		h.mixAlgoID()
+	}
+
+	return h
+}
+
+// NewXXH128 returns a new ImoHash that samples into a 128-bit hash built
+// from two differently-seeded XXH64 instances, using the default sample
+// parameters. XXH64 is considerably faster than murmur3 on amd64; since
+// it only produces 8 bytes on its own, two seeded instances are combined
+// to fill imohash's 16-byte digest without truncating either one.
+func NewXXH128() ImoHash {
+	return NewWithHash(func() hash.Hash {
+		return newWideHash(func(seed uint64) hash.Hash { return xxhash.NewWithSeed(seed) })
+	}, SampleSize, SampleThreshhold)
+}
+
+// NewBlake2b returns a new ImoHash that samples into a 16-byte blake2b
+// digest instead of murmur3, using the default sample parameters. Use
+// this preset when imohash is being used for integrity verification
+// rather than dedup, where a cryptographic hash is required.
+func NewBlake2b() ImoHash {
+	return NewWithHash(func() hash.Hash {
+		h, err := blake2b.New(Size, nil)
+		if err != nil {
+			panic(err)
+		}
+		return h
+	}, SampleSize, SampleThreshhold)
+}
+
+// mixAlgoID writes an identifier for the hasher's concrete type into the
+// hasher itself, so that e.g. an xxhash digest and a blake2b digest of
+// the same sampled bytes never collide.
+func (imo *ImoHash) mixAlgoID() {
+	var idBytes [8]byte
+	binary.BigEndian.PutUint64(idBytes[:], algoID(imo.hasher))
+	imo.hasher.Write(idBytes[:])
+}
+
+// algoID derives a stable identifier for a hash.Hash implementation from
+// its concrete type name.
+func algoID(h hash.Hash) uint64 {
+	sum := fnv.New64a()
+	sum.Write([]byte(reflect.TypeOf(h).String()))
+	return sum.Sum64()
+}
+
+// wideHash combines two 64-bit hash.Hash instances, differing only in
+// seed, into a single 16-byte hash.Hash. It exists because fast
+// non-cryptographic hashes in wide use (xxhash chief among them) are
+// natively 64-bit; neither half is truncated, so no collision
+// resistance is given up to reach imohash's 16-byte digest width.
+type wideHash struct {
+	lo, hi hash.Hash
+}
+
+// newWideHash builds a wideHash from two instances produced by mk, seeded
+// 0 and 1 respectively.
+func newWideHash(mk func(seed uint64) hash.Hash) *wideHash {
+	return &wideHash{lo: mk(0), hi: mk(1)}
+}
+
+func (w *wideHash) Write(p []byte) (int, error) {
+	w.lo.Write(p)
+	return w.hi.Write(p)
+}
+
+func (w *wideHash) Sum(b []byte) []byte {
+	sum := make([]byte, 0, w.Size())
+	sum = w.lo.Sum(sum)
+	sum = w.hi.Sum(sum)
+	return append(b, sum...)
+}
+
+func (w *wideHash) Reset() {
+	w.lo.Reset()
+	w.hi.Reset()
+}
+
+func (w *wideHash) Size() int { return w.lo.Size() + w.hi.Size() }
+
+func (w *wideHash) BlockSize() int { return w.lo.BlockSize() }
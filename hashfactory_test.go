@@ -0,0 +1,72 @@
+package imohash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"testing"
+
+	"github.com/spaolacci/murmur3"
+)
+
+func TestNewWithHashRejectsWrongDigestSize(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewWithHash did not panic for a hash backend with the wrong digest size")
+		}
+	}()
+	NewWithHash(func() hash.Hash { return fnv.New64a() }, SampleSize, SampleThreshhold)
+}
+
+func TestSumMatchesSizeContract(t *testing.T) {
+	backends := map[string]ImoHash{
+		"murmur3": New(),
+		"xxh128":  NewXXH128(),
+		"blake2b": NewBlake2b(),
+	}
+	for name, imo := range backends {
+		imo := imo
+		imo.Write([]byte("abc"))
+		sum := imo.Sum(nil)
+		if len(sum) != imo.Size() {
+			t.Errorf("%s: Sum returned %d bytes, Size() reports %d", name, len(sum), imo.Size())
+		}
+	}
+}
+
+func TestDefaultDigestUnaffectedByAlgoMixing(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+
+	got := Sum128(data)
+
+	want := murmur3.New128()
+	want.Write(data)
+	wantSum := want.Sum(nil)
+	binary.PutUvarint(wantSum, uint64(len(data)))
+
+	if !bytes.Equal(got[:], wantSum) {
+		t.Fatalf("New()'s digest no longer matches a bare murmur3.New128(): got %x, want %x", got, wantSum)
+	}
+}
+
+func TestWideHashProduces16ByteDigest(t *testing.T) {
+	w := newWideHash(func(seed uint64) hash.Hash { return fnv64aWithSeed(seed) })
+	if w.Size() != Size {
+		t.Fatalf("wideHash.Size() = %d, want %d", w.Size(), Size)
+	}
+	w.Write([]byte("payload"))
+	if sum := w.Sum(nil); len(sum) != Size {
+		t.Fatalf("wideHash.Sum produced %d bytes, want %d", len(sum), Size)
+	}
+}
+
+// fnv64aWithSeed stands in for xxhash.NewWithSeed in TestWideHashProduces16ByteDigest
+// so the test exercises wideHash with a standard-library 64-bit hash.
+func fnv64aWithSeed(seed uint64) hash.Hash {
+	h := fnv.New64a()
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], seed)
+	h.Write(b[:])
+	return h
+}
@@ -28,10 +28,14 @@ var (
 )
 
 type ImoHash struct {
-	hasher          murmur3.Hash128
-	sampleSize      int
-	sampleThreshold int
-	bytesAdded      int
+	hasher             hash.Hash
+	hasherFactory      HashFactory
+	offsets            OffsetFunc
+	usesDefaultOffsets bool
+	sampleSize         int
+	sampleThreshold    int
+	bytesAdded         int
+	mixAlgo            bool
 }
 
 // New returns a new ImoHash using the default sample size
@@ -44,13 +48,7 @@ func New() ImoHash {
 // and sample threshhold values. The entire file will be hashed
 // (i.e. no sampling), if sampleSize < 1.
 func NewCustom(sampleSize, sampleThreshold int) ImoHash {
-	h := ImoHash{
-		hasher:          murmur3.New128(),
-		sampleSize:      sampleSize,
-		sampleThreshold: sampleThreshold,
-	}
-
-	return h
+	return newImoHash(func() hash.Hash { return murmur3.New128() }, false, sampleSize, sampleThreshold)
 }
 
 // SumFile hashes a file using default sample parameters.
@@ -105,6 +103,9 @@ func (imo *ImoHash) BlockSize() int { return 1 }
 func (imo *ImoHash) Reset() {
 	imo.bytesAdded = 0
 	imo.hasher.Reset()
+	if imo.mixAlgo {
+		imo.mixAlgoID()
+	}
 }
 
 // Size returns the number of bytes Sum will return.
@@ -114,7 +115,7 @@ func (imo *ImoHash) Size() int { return Size }
 func (imo *ImoHash) hashCore(f *io.SectionReader) [Size]byte {
 	var result [Size]byte
 
-	imo.hasher.Reset()
+	imo.Reset()
 
 	if f.Size() < int64(imo.sampleThreshold) || imo.sampleSize < 1 {
 		buffer := make([]byte, f.Size())
@@ -122,14 +123,11 @@ func (imo *ImoHash) hashCore(f *io.SectionReader) [Size]byte {
 		imo.hasher.Write(buffer)
 	} else {
 		buffer := make([]byte, imo.sampleSize)
-		f.Read(buffer)
-		imo.hasher.Write(buffer)
-		f.Seek(f.Size()/2, 0)
-		f.Read(buffer)
-		imo.hasher.Write(buffer)
-		f.Seek(int64(-imo.sampleSize), 2)
-		f.Read(buffer)
-		imo.hasher.Write(buffer)
+		for _, offset := range imo.offsets(f.Size()) {
+			f.Seek(offset, io.SeekStart)
+			f.Read(buffer)
+			imo.hasher.Write(buffer)
+		}
 	}
 
 	hash := imo.hasher.Sum(nil)
@@ -0,0 +1,128 @@
+package imohash
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+)
+
+// marshalMagic identifies the serialized form produced by MarshalBinary,
+// so UnmarshalBinary can reject data written by an incompatible version.
+var marshalMagic = [4]byte{'i', 'm', 'o', 0x01}
+
+// MarshalBinary encodes the ImoHash's running state -- the underlying
+// hasher's state, the sample parameters, the byte count written so far,
+// and whether sampling uses the default head/middle/tail offsets -- so
+// that streaming, Write-based hashing can be checkpointed and resumed
+// across process restarts. The underlying hash backend must implement
+// encoding.BinaryMarshaler; murmur3.Hash128, the default, does not, so
+// this only works with a backend installed via NewWithHash (or a preset
+// such as NewBlake2b) that does.
+//
+// A custom sampling offsets function (set via NewCustomSampling) is a
+// closure and cannot itself be serialized; only the default offsets are
+// re-derived on unmarshal, from the restored sampleSize. Checkpointing
+// an ImoHash with custom offsets requires unmarshaling into an ImoHash
+// already configured with the identical sampleSize.
+func (imo *ImoHash) MarshalBinary() ([]byte, error) {
+	bm, ok := imo.hasher.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("imohash: hash backend %T does not implement encoding.BinaryMarshaler", imo.hasher)
+	}
+
+	state, err := bm.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, len(marshalMagic)+3*binary.MaxVarintLen64+1+len(state))
+	buf = append(buf, marshalMagic[:]...)
+	buf = appendUvarint(buf, uint64(imo.sampleSize))
+	buf = appendUvarint(buf, uint64(imo.sampleThreshold))
+	buf = appendUvarint(buf, uint64(imo.bytesAdded))
+	if imo.usesDefaultOffsets {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	buf = append(buf, state...)
+
+	return buf, nil
+}
+
+// UnmarshalBinary restores an ImoHash serialized by MarshalBinary. The
+// ImoHash's hasherFactory must already be set (e.g. via NewWithHash or a
+// preset such as NewBlake2b) so a fresh backend instance is available to
+// unmarshal its state into; that backend must implement
+// encoding.BinaryUnmarshaler, which the murmur3 default used by New and
+// NewCustom does not.
+func (imo *ImoHash) UnmarshalBinary(data []byte) error {
+	if len(data) < len(marshalMagic) {
+		return fmt.Errorf("imohash: marshaled data too short")
+	}
+	var magic [4]byte
+	copy(magic[:], data[:4])
+	if magic != marshalMagic {
+		return fmt.Errorf("imohash: marshaled data has an unrecognized or incompatible magic prefix")
+	}
+	data = data[4:]
+
+	sampleSize, n := binary.Uvarint(data)
+	if n <= 0 {
+		return fmt.Errorf("imohash: malformed sampleSize in marshaled data")
+	}
+	data = data[n:]
+
+	sampleThreshold, n := binary.Uvarint(data)
+	if n <= 0 {
+		return fmt.Errorf("imohash: malformed sampleThreshold in marshaled data")
+	}
+	data = data[n:]
+
+	bytesAdded, n := binary.Uvarint(data)
+	if n <= 0 {
+		return fmt.Errorf("imohash: malformed bytesAdded in marshaled data")
+	}
+	data = data[n:]
+
+	if len(data) < 1 {
+		return fmt.Errorf("imohash: marshaled data missing the default-offsets flag")
+	}
+	usesDefaultOffsets := data[0] != 0
+	data = data[1:]
+
+	if !usesDefaultOffsets && int(sampleSize) != imo.sampleSize {
+		return fmt.Errorf("imohash: cannot restore a custom-sampling checkpoint (sampleSize %d) into an ImoHash configured with a different sample size (%d); the offsets function cannot be serialized, so the sizes must match", sampleSize, imo.sampleSize)
+	}
+
+	if imo.hasherFactory == nil {
+		return fmt.Errorf("imohash: cannot unmarshal into an ImoHash with no hash backend")
+	}
+	h := imo.hasherFactory()
+
+	bu, ok := h.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return fmt.Errorf("imohash: hash backend %T does not implement encoding.BinaryUnmarshaler", h)
+	}
+	if err := bu.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	imo.hasher = h
+	imo.sampleSize = int(sampleSize)
+	imo.sampleThreshold = int(sampleThreshold)
+	imo.bytesAdded = int(bytesAdded)
+	imo.usesDefaultOffsets = usesDefaultOffsets
+	if usesDefaultOffsets {
+		imo.offsets = OffsetsHeadMidTail(imo.sampleSize)
+	}
+
+	return nil
+}
+
+// appendUvarint encodes v as a varint and appends it to buf.
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
@@ -0,0 +1,72 @@
+package imohash
+
+import (
+	"testing"
+)
+
+func TestMarshalUnmarshalRestoresDefaultOffsets(t *testing.T) {
+	src := NewBlake2b()
+	src.Write([]byte("checkpoint me"))
+
+	data, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	dst := NewBlake2b()
+	if err := dst.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+
+	if !dst.usesDefaultOffsets {
+		t.Fatal("UnmarshalBinary did not mark the restored ImoHash as using default offsets")
+	}
+	if dst.offsets == nil {
+		t.Fatal("UnmarshalBinary left offsets nil")
+	}
+
+	gotOffsets := dst.offsets(1 << 20)
+	wantOffsets := OffsetsHeadMidTail(dst.sampleSize)(1 << 20)
+	if len(gotOffsets) != len(wantOffsets) {
+		t.Fatalf("restored offsets = %v, want %v", gotOffsets, wantOffsets)
+	}
+	for i := range gotOffsets {
+		if gotOffsets[i] != wantOffsets[i] {
+			t.Fatalf("restored offsets = %v, want %v", gotOffsets, wantOffsets)
+		}
+	}
+}
+
+func TestUnmarshalRejectsMismatchedCustomSamplingSize(t *testing.T) {
+	offsets := OffsetsEvenlySpaced(4, 8*1024)
+
+	src := NewCustomSampling(8*1024, SampleThreshhold, offsets)
+	// NewCustomSampling builds on NewCustom, which uses murmur3 by
+	// default and doesn't implement encoding.BinaryMarshaler; swap in a
+	// marshalable backend directly so MarshalBinary can succeed.
+	src = withBlake2bBackend(src)
+	src.Write([]byte("checkpoint me"))
+
+	data, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	dst := NewCustomSampling(4*1024, SampleThreshhold, offsets)
+	dst = withBlake2bBackend(dst)
+	if err := dst.UnmarshalBinary(data); err == nil {
+		t.Fatal("expected UnmarshalBinary to reject a custom-sampling checkpoint restored at a different sample size")
+	}
+}
+
+// withBlake2bBackend swaps h's hash backend for blake2b (which supports
+// encoding.BinaryMarshaler/Unmarshaler) while preserving its sampling
+// configuration, so marshal tests can exercise non-default offsets
+// without depending on murmur3 gaining that support.
+func withBlake2bBackend(h ImoHash) ImoHash {
+	blake := NewBlake2b()
+	h.hasher = blake.hasher
+	h.hasherFactory = blake.hasherFactory
+	h.mixAlgo = blake.mixAlgo
+	return h
+}
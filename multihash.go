@@ -0,0 +1,61 @@
+package imohash
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// defaultMultihashCode is a placeholder multicodec code for imohash
+// digests; it is not registered in the multicodec table. Callers
+// embedding imohash digests in multihash-aware tooling (IPFS, CID
+// libraries) should call RegisterMultihashCode with a code reserved for
+// them before producing multihashes meant to leave the process.
+const defaultMultihashCode = 0x3f0000
+
+var multihashCode uint64 = defaultMultihashCode
+
+// RegisterMultihashCode sets the multicodec code written by SumMultihash
+// and SumMultihashReader. It is not safe to call concurrently with
+// hashing.
+func RegisterMultihashCode(code uint64) {
+	multihashCode = code
+}
+
+// SumMultihash hashes a file using default sample parameters and wraps
+// the digest in a multiformats multihash TLV
+// (<varint code><varint length><digest>), so it can flow through
+// multihash-aware tooling such as IPFS CID libraries without a second
+// wrapping layer. The 16-byte imohash digest (which already encodes the
+// file-size varint in its tail) becomes the TLV's value unchanged.
+func SumMultihash(filename string) ([]byte, error) {
+	imo := New()
+	sum, err := imo.SumFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return wrapMultihash(sum), nil
+}
+
+// SumMultihashReader hashes r, using the ImoHash parameters, and wraps
+// the digest in a multihash TLV. size is the total number of bytes r
+// will yield, as with SumReader.
+func (imo *ImoHash) SumMultihashReader(r io.Reader, size int64) ([]byte, error) {
+	sum, err := imo.SumReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return wrapMultihash(sum), nil
+}
+
+// wrapMultihash prepends the registered multicodec code and the digest
+// length to sum, per the multihash spec.
+func wrapMultihash(sum [Size]byte) []byte {
+	var header [2 * binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(header[:], multihashCode)
+	n += binary.PutUvarint(header[n:], uint64(Size))
+
+	buf := make([]byte, 0, n+Size)
+	buf = append(buf, header[:n]...)
+	buf = append(buf, sum[:]...)
+	return buf
+}
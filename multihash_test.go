@@ -0,0 +1,89 @@
+package imohash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSumMultihashWrapsDigest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, []byte("multihash me"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mh, err := SumMultihash(path)
+	if err != nil {
+		t.Fatalf("SumMultihash: %v", err)
+	}
+
+	code, n := binary.Uvarint(mh)
+	if n <= 0 {
+		t.Fatal("multihash has no valid code varint")
+	}
+	mh = mh[n:]
+	if code != defaultMultihashCode {
+		t.Fatalf("code = %d, want %d", code, defaultMultihashCode)
+	}
+
+	length, n := binary.Uvarint(mh)
+	if n <= 0 {
+		t.Fatal("multihash has no valid length varint")
+	}
+	mh = mh[n:]
+	if length != Size {
+		t.Fatalf("length = %d, want %d", length, Size)
+	}
+	if len(mh) != Size {
+		t.Fatalf("digest is %d bytes, want %d", len(mh), Size)
+	}
+
+	want, err := SumFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(mh, want[:]) {
+		t.Fatalf("wrapped digest = %x, want %x", mh, want)
+	}
+}
+
+func TestSumMultihashReaderMatchesSumMultihash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	data := []byte("multihash via reader")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	viaFile, err := SumMultihash(path)
+	if err != nil {
+		t.Fatalf("SumMultihash: %v", err)
+	}
+
+	imo := New()
+	viaReader, err := imo.SumMultihashReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("SumMultihashReader: %v", err)
+	}
+
+	if !bytes.Equal(viaFile, viaReader) {
+		t.Fatalf("SumMultihash = %x, SumMultihashReader = %x", viaFile, viaReader)
+	}
+}
+
+func TestRegisterMultihashCode(t *testing.T) {
+	prev := multihashCode
+	defer RegisterMultihashCode(prev)
+
+	RegisterMultihashCode(0x99)
+	wrapped := wrapMultihash([Size]byte{})
+
+	code, n := binary.Uvarint(wrapped)
+	if n <= 0 {
+		t.Fatal("multihash has no valid code varint")
+	}
+	if code != 0x99 {
+		t.Fatalf("code = %d, want 0x99", code)
+	}
+}
@@ -0,0 +1,92 @@
+package imohash
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// SumReader hashes data read from r using default sample parameters. The
+// total size of r's contents must be known in advance and passed in size,
+// since it both determines whether sampling occurs and is encoded in the
+// digest tail.
+func SumReader(r io.Reader, size int64) ([Size]byte, error) {
+	imo := New()
+	return imo.SumReader(r, size)
+}
+
+// SumReaderAt hashes data read from r using default sample parameters,
+// seeking directly to each sample window.
+func SumReaderAt(r io.ReaderAt, size int64) [Size]byte {
+	imo := New()
+	return imo.SumReaderAt(r, size)
+}
+
+// SumReader hashes data read from r, using the ImoHash parameters. Unlike
+// SumFile and SumReaderAt, r need not support seeking or random access
+// (a pipe, an HTTP response body, or a tar entry all work); the bytes
+// that fall between sample windows are simply discarded via io.CopyN.
+// size must be the exact number of bytes r will yield.
+func (imo *ImoHash) SumReader(r io.Reader, size int64) ([Size]byte, error) {
+	return imo.hashCoreReader(r, size)
+}
+
+// SumReaderAt hashes data read from r, using the ImoHash parameters. r
+// must support random access (e.g. *os.File, *bytes.Reader, or an S3
+// range-reader); each sample window is read directly via ReadAt, so no
+// bytes are read and discarded as they are with SumReader.
+func (imo *ImoHash) SumReaderAt(r io.ReaderAt, size int64) [Size]byte {
+	sr := io.NewSectionReader(r, 0, size)
+	return imo.hashCore(sr)
+}
+
+// hashCoreReader hashes a plain io.Reader of the given size using the
+// ImoHash parameters, discarding the bytes between sample windows with
+// io.CopyN since r cannot seek. Sample windows are placed by imo.offsets,
+// same as hashCore; unlike hashCore, r cannot rewind to re-read a window
+// that overlaps the one before it, so an offset that lands behind where
+// the reader already is produces a clear error here rather than a
+// misleading EOF from trying to read past the end of the stream.
+func (imo *ImoHash) hashCoreReader(r io.Reader, size int64) ([Size]byte, error) {
+	var result [Size]byte
+
+	imo.Reset()
+
+	if size < int64(imo.sampleThreshold) || imo.sampleSize < 1 {
+		buffer := make([]byte, size)
+		if _, err := io.ReadFull(r, buffer); err != nil {
+			return emptyArray, err
+		}
+		imo.hasher.Write(buffer)
+	} else {
+		buffer := make([]byte, imo.sampleSize)
+
+		offsets := append([]int64(nil), imo.offsets(size)...)
+		sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+		var pos int64
+		for _, offset := range offsets {
+			if offset < pos {
+				return emptyArray, fmt.Errorf("imohash: offsets function produced an overlapping or non-monotonic window at %d after reading through %d; SumReader requires non-overlapping offsets (use SumReaderAt or SumFile instead)", offset, pos)
+			}
+			if offset > pos {
+				if _, err := io.CopyN(io.Discard, r, offset-pos); err != nil {
+					return emptyArray, err
+				}
+				pos = offset
+			}
+			if _, err := io.ReadFull(r, buffer); err != nil {
+				return emptyArray, err
+			}
+			imo.hasher.Write(buffer)
+			pos += int64(imo.sampleSize)
+		}
+	}
+
+	hash := imo.hasher.Sum(nil)
+	binary.PutUvarint(hash, uint64(size))
+	copy(result[:], hash)
+
+	return result, nil
+}
@@ -0,0 +1,43 @@
+package imohash
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSumReaderMatchesSumReaderAt(t *testing.T) {
+	data := make([]byte, SampleThreshhold*2)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	viaReader, err := SumReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("SumReader: %v", err)
+	}
+	viaReaderAt := SumReaderAt(bytes.NewReader(data), int64(len(data)))
+
+	if viaReader != viaReaderAt {
+		t.Fatalf("SumReader = %x, SumReaderAt = %x", viaReader, viaReaderAt)
+	}
+}
+
+func TestSumReaderBelowThreshold(t *testing.T) {
+	data := []byte("short data, hashed in full")
+
+	viaReader, err := SumReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("SumReader: %v", err)
+	}
+	if want := Sum128(data); viaReader != want {
+		t.Fatalf("SumReader = %x, want %x", viaReader, want)
+	}
+}
+
+func TestSumReaderErrorsOnShortInput(t *testing.T) {
+	data := make([]byte, SampleThreshhold*2)
+	// Claim more bytes than the reader actually yields.
+	if _, err := SumReader(bytes.NewReader(data), int64(len(data))+1); err == nil {
+		t.Fatal("expected SumReader to error when size exceeds what r yields")
+	}
+}
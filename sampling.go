@@ -0,0 +1,146 @@
+package imohash
+
+import "sort"
+
+// OffsetFunc computes the start offsets of the sample windows hashCore
+// reads for a file of the given size. Each window is sampleSize bytes
+// long; an OffsetFunc is expected to keep every offset within
+// [0, size-sampleSize] and the resulting windows non-overlapping.
+type OffsetFunc func(size int64) []int64
+
+// NewCustomSampling returns a new ImoHash using the provided sample size
+// and sample threshhold values, with sample windows placed by offsets
+// instead of the default head/middle/tail layout. More windows trade
+// throughput for a lower false-collision rate on adversarial inputs such
+// as large sparse or block-repetitive files.
+func NewCustomSampling(sampleSize, sampleThreshold int, offsets OffsetFunc) ImoHash {
+	h := NewCustom(sampleSize, sampleThreshold)
+	h.offsets = offsets
+	h.usesDefaultOffsets = false
+	return h
+}
+
+// OffsetsHeadMidTail is imohash's original, default sampling strategy:
+// one window at the start of the file, one at the midpoint, and one
+// ending at EOF.
+func OffsetsHeadMidTail(sampleSize int) OffsetFunc {
+	return func(size int64) []int64 {
+		return []int64{0, size / 2, size - int64(sampleSize)}
+	}
+}
+
+// OffsetsEvenlySpaced returns an OffsetFunc that places n sample windows
+// at even intervals across the file, from offset 0 to size-sampleSize.
+// A file can only fit size/sampleSize non-overlapping windows; if n
+// exceeds that, n is capped to the file's actual capacity, so no bytes
+// are hashed more than once.
+func OffsetsEvenlySpaced(n, sampleSize int) OffsetFunc {
+	return func(size int64) []int64 {
+		n := capOffsetCount(n, sampleSize, size)
+		if n < 1 {
+			return nil
+		}
+
+		max := size - int64(sampleSize)
+		offsets := make([]int64, n)
+		for i := 0; i < n; i++ {
+			if n == 1 {
+				offsets[i] = 0
+				continue
+			}
+			offsets[i] = max * int64(i) / int64(n-1)
+		}
+		return clampOffsets(offsets, sampleSize, max)
+	}
+}
+
+// OffsetsPseudoRandom returns an OffsetFunc that places n sample windows
+// at offsets derived from a splitmix64 sequence seeded by the file size
+// and seed. splitmix64 only needs to produce a reproducible sequence of
+// well-mixed values, not murmur3's actual hashing properties, and -
+// unlike murmur3.Sum32WithSeed's unsafe-pointer fast path - it does so
+// without tripping `go test -race`'s checkptr instrumentation. For a
+// given (size, seed, n) the sequence is reproducible, so two runs
+// against the same file always sample the same windows. As with
+// OffsetsEvenlySpaced, n is capped to the number of non-overlapping
+// windows the file can actually fit.
+func OffsetsPseudoRandom(n int, seed uint32, sampleSize int) OffsetFunc {
+	return func(size int64) []int64 {
+		n := capOffsetCount(n, sampleSize, size)
+		if n < 1 {
+			return nil
+		}
+
+		max := size - int64(sampleSize)
+
+		state := uint64(seed)*0x9e3779b97f4a7c15 ^ uint64(size)
+		offsets := make([]int64, n)
+		for i := range offsets {
+			offsets[i] = int64(splitmix64(&state) % uint64(max+1))
+		}
+		return clampOffsets(offsets, sampleSize, max)
+	}
+}
+
+// splitmix64 advances state in place and returns the next value in its
+// pseudo-random sequence. It's a standard, well-mixed generator small
+// enough to inline here instead of pulling in a dependency for what is
+// ultimately just a reproducible shuffle of sample offsets.
+func splitmix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// capOffsetCount reduces n to the number of non-overlapping sampleSize
+// windows that actually fit in a file of size, so callers get fewer
+// distinct samples instead of silently repeated ones.
+func capOffsetCount(n, sampleSize int, size int64) int {
+	if n < 1 || sampleSize < 1 || size < int64(sampleSize) {
+		return 0
+	}
+	if capacity := int(size / int64(sampleSize)); capacity < n {
+		return capacity
+	}
+	return n
+}
+
+// clampOffsets sorts offsets ascending and spreads them into genuinely
+// non-overlapping sampleSize-byte windows within [0, max]. Given n
+// capped to capOffsetCount's capacity, size >= n*sampleSize, so
+// max >= (n-1)*sampleSize and there is always enough room to do so.
+//
+// A single forward pass (nudge each offset past the one before it) can
+// push the last offset beyond max; a single backward pass (pull each
+// offset below the one after it) can equally push the first offset
+// below 0. So this runs forward then backward: the forward pass
+// resolves overlaps from the low end, then the backward pass -- anchored
+// at max, which the capacity precondition guarantees fits -- pulls
+// anything that pass pushed too high back down, without reopening any
+// overlap (each step only shrinks a gap that was already >= sampleSize).
+func clampOffsets(offsets []int64, sampleSize int, max int64) []int64 {
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	n := len(offsets)
+
+	for i := 1; i < n; i++ {
+		if offsets[i] < offsets[i-1]+int64(sampleSize) {
+			offsets[i] = offsets[i-1] + int64(sampleSize)
+		}
+	}
+
+	if offsets[n-1] > max {
+		offsets[n-1] = max
+	}
+	for i := n - 2; i >= 0; i-- {
+		if offsets[i] > offsets[i+1]-int64(sampleSize) {
+			offsets[i] = offsets[i+1] - int64(sampleSize)
+		}
+	}
+
+	if offsets[0] < 0 {
+		offsets[0] = 0
+	}
+	return offsets
+}
@@ -0,0 +1,78 @@
+package imohash
+
+import (
+	"bytes"
+	"testing"
+)
+
+func assertNonOverlapping(t *testing.T, offsets []int64, sampleSize int, size int64) {
+	t.Helper()
+	for i, off := range offsets {
+		if off < 0 || off+int64(sampleSize) > size {
+			t.Fatalf("offset %d (%d) out of bounds for size %d, sampleSize %d", i, off, size, sampleSize)
+		}
+		if i > 0 && off < offsets[i-1]+int64(sampleSize) {
+			t.Fatalf("offsets[%d]=%d overlaps offsets[%d]=%d (sampleSize %d)", i, off, i-1, offsets[i-1], sampleSize)
+		}
+	}
+}
+
+func TestOffsetsEvenlySpacedCapsToCapacity(t *testing.T) {
+	const sampleSize = 16 * 1024
+	const size = 64 * 1024 // fits only 4 non-overlapping sampleSize windows
+
+	offsets := OffsetsEvenlySpaced(10, sampleSize)(size)
+
+	if len(offsets) > 4 {
+		t.Fatalf("got %d offsets for a file that only fits 4 non-overlapping windows: %v", len(offsets), offsets)
+	}
+	assertNonOverlapping(t, offsets, sampleSize, size)
+}
+
+func TestOffsetsPseudoRandomCapsToCapacity(t *testing.T) {
+	const sampleSize = 16 * 1024
+	const size = 64 * 1024
+
+	offsets := OffsetsPseudoRandom(10, 42, sampleSize)(size)
+
+	if len(offsets) > 4 {
+		t.Fatalf("got %d offsets for a file that only fits 4 non-overlapping windows: %v", len(offsets), offsets)
+	}
+	assertNonOverlapping(t, offsets, sampleSize, size)
+}
+
+func TestSumReaderRejectsOverlappingOffsets(t *testing.T) {
+	const sampleSize = 16 * 1024
+	const size = 64 * 1024
+	// Below SampleThreshhold, hashCore/hashCoreReader hash the whole
+	// buffer and never consult offsets at all; use a threshold below size
+	// so the sampling branch -- and the overlap check in it -- actually
+	// runs.
+	const threshold = sampleSize
+
+	imo := NewCustomSampling(sampleSize, threshold, func(size int64) []int64 {
+		// Two windows that overlap by sampleSize/2, which SumReader cannot
+		// satisfy without rewinding.
+		return []int64{0, sampleSize / 2}
+	})
+
+	data := make([]byte, size)
+	if _, err := imo.SumReader(bytes.NewReader(data), size); err == nil {
+		t.Fatal("expected SumReader to reject overlapping offsets, got nil error")
+	}
+}
+
+func TestSumReaderAtToleratesOverlappingOffsets(t *testing.T) {
+	const sampleSize = 16 * 1024
+	const size = 64 * 1024
+	const threshold = sampleSize
+
+	imo := NewCustomSampling(sampleSize, threshold, func(size int64) []int64 {
+		return []int64{0, sampleSize / 2}
+	})
+
+	data := make([]byte, size)
+	// SumReaderAt can seek backwards, so the same overlapping offsets that
+	// SumReader rejects must still succeed here.
+	imo.SumReaderAt(bytes.NewReader(data), size)
+}
@@ -0,0 +1,207 @@
+package imohash
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ignoreFileName is the name of the ignore file SumTree honors, modeled
+// on .gitignore: one glob pattern per line, matched against each entry's
+// path (and base name) relative to root.
+const ignoreFileName = ".imoignore"
+
+// FileHash is a single result streamed from SumTree.
+type FileHash struct {
+	Path string
+	Size int64
+	Hash [Size]byte
+	Err  error
+}
+
+// TreeOptions configures SumTree.
+type TreeOptions struct {
+	// FollowSymlinks causes symlinks to files to be hashed instead of
+	// skipped. Symlinks to directories are never followed, to avoid
+	// infinite loops from cycles.
+	FollowSymlinks bool
+
+	// MaxDepth limits how many directories deep SumTree descends below
+	// root. A value <= 0 means no limit.
+	MaxDepth int
+
+	// Workers sets the size of the worker pool used to hash files
+	// concurrently. A value <= 0 defaults to runtime.NumCPU().
+	Workers int
+}
+
+// SumTree walks the directory tree rooted at root and hashes every
+// regular file (subject to opts and any root/.imoignore file) with a
+// bounded worker pool, streaming results on the returned channel. The
+// channel is closed once every matched file has been hashed.
+//
+// Because imohash samples in constant time, hashing a large tree this
+// way is an I/O-bound job dominated by open+seek+read, not CPU, so a
+// worker pool turns "hash 1M files" into something that actually
+// saturates the disk instead of running one file at a time.
+func SumTree(root string, opts TreeOptions) (<-chan FileHash, error) {
+	patterns, err := loadIgnorePatterns(root)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	paths := make(chan string, workers)
+	results := make(chan FileHash, workers)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			imo := New()
+			for path := range paths {
+				fh := FileHash{Path: path}
+				if fi, err := os.Stat(path); err != nil {
+					fh.Err = err
+				} else {
+					fh.Size = fi.Size()
+					fh.Hash, fh.Err = imo.SumFile(path)
+				}
+				results <- fh
+			}
+		}()
+	}
+
+	go func() {
+		defer close(paths)
+		if err := walkTree(root, opts, patterns, func(path string) {
+			paths <- path
+		}); err != nil {
+			results <- FileHash{Path: root, Err: err}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results, nil
+}
+
+// Deduplicate consumes every result from a SumTree channel and groups
+// successfully hashed files by digest -- the primary use case that
+// motivated croc's switch to imohash -- discarding groups with only a
+// single member. Files that failed to hash are returned separately
+// rather than silently dropped.
+func Deduplicate(results <-chan FileHash) (groups map[[Size]byte][]string, errs []FileHash) {
+	groups = make(map[[Size]byte][]string)
+	for fh := range results {
+		if fh.Err != nil {
+			errs = append(errs, fh)
+			continue
+		}
+		groups[fh.Hash] = append(groups[fh.Hash], fh.Path)
+	}
+	for hash, paths := range groups {
+		if len(paths) < 2 {
+			delete(groups, hash)
+		}
+	}
+	return groups, errs
+}
+
+// walkTree walks root, invoking visit for every file that should be
+// hashed given opts and patterns.
+func walkTree(root string, opts TreeOptions, patterns []string, visit func(path string)) error {
+	rootDepth := strings.Count(filepath.Clean(root), string(filepath.Separator))
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." && isIgnored(patterns, rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			if opts.MaxDepth > 0 {
+				depth := strings.Count(filepath.Clean(path), string(filepath.Separator)) - rootDepth
+				if depth > opts.MaxDepth {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+
+		typ := d.Type()
+		if typ&fs.ModeSymlink != 0 {
+			if !opts.FollowSymlinks {
+				return nil
+			}
+			fi, err := os.Stat(path)
+			if err != nil || !fi.Mode().IsRegular() {
+				return nil
+			}
+		} else if !typ.IsRegular() {
+			return nil
+		}
+
+		visit(path)
+		return nil
+	})
+}
+
+// loadIgnorePatterns reads root/.imoignore, if present, returning one
+// pattern per non-blank, non-comment line.
+func loadIgnorePatterns(root string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(root, ignoreFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, nil
+}
+
+// isIgnored reports whether rel (or its base name) matches any pattern.
+func isIgnored(patterns []string, rel string) bool {
+	rel = filepath.ToSlash(rel)
+	base := filepath.Base(rel)
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(p, base); ok {
+			return true
+		}
+	}
+	return false
+}
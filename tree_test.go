@@ -0,0 +1,91 @@
+package imohash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func mustWriteFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSumTreeAndDeduplicate(t *testing.T) {
+	root := t.TempDir()
+
+	mustWriteFile(t, filepath.Join(root, "a.txt"), "same contents")
+	mustWriteFile(t, filepath.Join(root, "b.txt"), "same contents")
+	mustWriteFile(t, filepath.Join(root, "c.txt"), "different contents")
+	mustWriteFile(t, filepath.Join(root, "ignored.log"), "should be skipped")
+	mustWriteFile(t, filepath.Join(root, ignoreFileName), "*.log\n")
+
+	results, err := SumTree(root, TreeOptions{})
+	if err != nil {
+		t.Fatalf("SumTree: %v", err)
+	}
+
+	groups, errs := Deduplicate(results)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	var dupSets int
+	for _, paths := range groups {
+		dupSets++
+		for _, p := range paths {
+			if filepath.Base(p) == "ignored.log" {
+				t.Fatalf("SumTree visited %s, which .imoignore should have excluded", p)
+			}
+		}
+	}
+	if dupSets != 1 {
+		t.Fatalf("got %d duplicate groups, want 1 (a.txt and b.txt)", dupSets)
+	}
+}
+
+func TestSumTreeMaxDepth(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	mustWriteFile(t, filepath.Join(root, "top.txt"), "top")
+	mustWriteFile(t, filepath.Join(nested, "deep.txt"), "deep")
+
+	results, err := SumTree(root, TreeOptions{MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("SumTree: %v", err)
+	}
+
+	var seen []string
+	for fh := range results {
+		if fh.Err != nil {
+			t.Fatalf("unexpected error for %s: %v", fh.Path, fh.Err)
+		}
+		seen = append(seen, filepath.Base(fh.Path))
+	}
+
+	if len(seen) != 1 || seen[0] != "top.txt" {
+		t.Fatalf("got %v, want only top.txt with MaxDepth 1", seen)
+	}
+}
+
+func TestIsIgnoredMatchesPathAndBase(t *testing.T) {
+	patterns := []string{"*.log", "build"}
+
+	cases := map[string]bool{
+		"output.log":     true,
+		"sub/output.log": true,
+		"build":          true,
+		"sub/build":      true,
+		"keep.txt":       false,
+	}
+	for rel, want := range cases {
+		if got := isIgnored(patterns, rel); got != want {
+			t.Errorf("isIgnored(%v, %q) = %v, want %v", patterns, rel, got, want)
+		}
+	}
+}